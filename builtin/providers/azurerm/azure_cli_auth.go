@@ -0,0 +1,130 @@
+package azurerm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/Azure/azure-sdk-for-go/Godeps/_workspace/src/github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/azure-sdk-for-go/Godeps/_workspace/src/github.com/Azure/go-autorest/autorest/azure"
+	"github.com/mitchellh/go-homedir"
+)
+
+// azureCliProfile mirrors the relevant subset of the JSON written by `az login` to
+// ~/.azure/azureProfile.json.
+type azureCliProfile struct {
+	Subscriptions []struct {
+		ID        string `json:"id"`
+		TenantID  string `json:"tenantId"`
+		IsDefault bool   `json:"isDefault"`
+	} `json:"subscriptions"`
+}
+
+// azureCliAccessToken mirrors the relevant subset of one entry of the JSON array `az login`
+// caches to ~/.azure/accessTokens.json.
+type azureCliAccessToken struct {
+	TokenType    string `json:"tokenType"`
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	ClientID     string `json:"_clientId"`
+	Resource     string `json:"resource"`
+	ExpiresOn    string `json:"expiresOn"`
+	UserID       string `json:"userId"`
+}
+
+// authorizerFromAzureCliProfile builds an autorest.Authorizer from the tokens cached by an
+// `az login` session, for the use_cli_credentials auth mode. It reads the default
+// subscription's tenant out of azureProfile.json, finds the matching cached management-plane
+// token in accessTokens.json, and wraps it as a manual-token ServicePrincipalToken so it can be
+// refreshed like any other. It returns the discovered subscription and tenant IDs rather than
+// mutating Config itself; the caller decides whether to adopt them.
+func authorizerFromAzureCliProfile(env azure.Environment) (autorest.Authorizer, string, string, error) {
+	profilePath, err := azureCliProfilePath()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	data, err := ioutil.ReadFile(profilePath)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("Error reading Azure CLI profile at %q: %s", profilePath, err)
+	}
+
+	var profile azureCliProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, "", "", fmt.Errorf("Error parsing Azure CLI profile at %q: %s", profilePath, err)
+	}
+
+	var tenantID, subscriptionID string
+	for _, sub := range profile.Subscriptions {
+		if sub.IsDefault {
+			tenantID = sub.TenantID
+			subscriptionID = sub.ID
+			break
+		}
+	}
+	if tenantID == "" {
+		return nil, "", "", fmt.Errorf("No default subscription found in Azure CLI profile at %q; run `az account set`", profilePath)
+	}
+
+	token, err := azureCliAccessTokenForResource(env.ResourceManagerEndpoint)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	oauthConfig, err := env.OAuthConfigForTenant(tenantID)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("Error building OAuth config for tenant %q: %s", tenantID, err)
+	}
+
+	spt, err := azure.NewServicePrincipalTokenFromManualToken(*oauthConfig, token.ClientID, env.ResourceManagerEndpoint, azure.Token{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		ExpiresIn:    "0",
+		ExpiresOn:    token.ExpiresOn,
+		Resource:     token.Resource,
+		Type:         token.TokenType,
+	})
+	if err != nil {
+		return nil, "", "", fmt.Errorf("Error building authorizer from Azure CLI cached token: %s", err)
+	}
+
+	return autorest.NewBearerAuthorizer(spt), subscriptionID, tenantID, nil
+}
+
+// azureCliAccessTokenForResource finds the cached token in accessTokens.json scoped to the
+// given resource (the ARM endpoint we authenticate against for this environment).
+func azureCliAccessTokenForResource(resource string) (*azureCliAccessToken, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, fmt.Errorf("Error determining home directory for Azure CLI tokens: %s", err)
+	}
+	tokensPath := filepath.Join(home, ".azure", "accessTokens.json")
+
+	data, err := ioutil.ReadFile(tokensPath)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading Azure CLI cached tokens at %q: %s", tokensPath, err)
+	}
+
+	var tokens []azureCliAccessToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("Error parsing Azure CLI cached tokens at %q: %s", tokensPath, err)
+	}
+
+	for _, token := range tokens {
+		if token.Resource == resource {
+			return &token, nil
+		}
+	}
+
+	return nil, fmt.Errorf("No cached Azure CLI token found for resource %q in %q; run `az login`", resource, tokensPath)
+}
+
+func azureCliProfilePath() (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", fmt.Errorf("Error determining home directory for Azure CLI profile: %s", err)
+	}
+
+	return filepath.Join(home, ".azure", "azureProfile.json"), nil
+}
@@ -0,0 +1,65 @@
+package azurerm
+
+import (
+	"github.com/Azure/azure-sdk-for-go/Godeps/_workspace/src/github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/Azure/azure-sdk-for-go/arm/resources/resources"
+	"github.com/Azure/azure-sdk-for-go/arm/storage"
+)
+
+// ArmClient holds the set of ARM management clients the provider's resources call into.
+// Each client is built once in getArmClient, sharing the Authorizer and per-environment
+// BaseURI that buildAuthorizer resolves for the configured auth mode.
+type ArmClient struct {
+	providers            resources.ProvidersClient
+	storageServiceClient storage.AccountsClient
+	vmClient             compute.VirtualMachinesClient
+
+	// environment is the azure.Environment buildAuthorizer resolved from the "environment"
+	// provider argument; resources use it for endpoints (e.g. storage's EndpointSuffix)
+	// that aren't exposed on the ARM SDK clients themselves.
+	environment azure.Environment
+
+	// poll bounds pollIndefinitelyAsNeeded for every client built from this Config, so the
+	// "poll_timeout"/"poll_interval"/"max_poll_attempts" provider arguments are threaded
+	// through explicitly rather than read from shared state.
+	poll pollSettings
+}
+
+// getArmClient resolves an Authorizer and the per-cloud endpoints for the configured auth
+// mode (client credentials, MSI, or an Azure CLI profile) via buildAuthorizer, then builds
+// every ARM client against that environment's Resource Manager endpoint.
+func (c *Config) getArmClient() (*ArmClient, error) {
+	authorizer, env, err := c.buildAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	providersClient := resources.NewProvidersClientWithBaseURI(env.ResourceManagerEndpoint, c.SubscriptionID)
+	providersClient.Authorizer = authorizer
+
+	storageClient := storage.NewAccountsClientWithBaseURI(env.ResourceManagerEndpoint, c.SubscriptionID)
+	storageClient.Authorizer = authorizer
+
+	vmClient := compute.NewVirtualMachinesClientWithBaseURI(env.ResourceManagerEndpoint, c.SubscriptionID)
+	vmClient.Authorizer = authorizer
+
+	poll := defaultPollSettings
+	if c.PollTimeout > 0 {
+		poll.timeout = c.PollTimeout
+	}
+	if c.PollInterval > 0 {
+		poll.interval = c.PollInterval
+	}
+	if c.MaxPollAttempts > 0 {
+		poll.maxAttempts = c.MaxPollAttempts
+	}
+
+	return &ArmClient{
+		providers:            providersClient,
+		storageServiceClient: storageClient,
+		vmClient:             vmClient,
+		environment:          env,
+		poll:                 poll,
+	}, nil
+}
@@ -1,44 +1,99 @@
 package azurerm
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"math/rand"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/Godeps/_workspace/src/github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/azure-sdk-for-go/Godeps/_workspace/src/github.com/Azure/go-autorest/autorest/azure"
 	"github.com/hashicorp/terraform/helper/mutexkv"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 )
 
+// msiEndpoint is the well-known IMDS endpoint VM extensions use to fetch an MSI token.
+const msiEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
 // Provider returns a terraform.ResourceProvider.
 func Provider() terraform.ResourceProvider {
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"subscription_id": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("ARM_SUBSCRIPTION_ID", ""),
 			},
 
 			"client_id": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("ARM_CLIENT_ID", ""),
 			},
 
 			"client_secret": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("ARM_CLIENT_SECRET", ""),
 			},
 
 			"tenant_id": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("ARM_TENANT_ID", ""),
 			},
+
+			"use_msi": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ARM_USE_MSI", false),
+				Description: "Authenticate via the Managed Service Identity of the VM running Terraform, instead of a service principal.",
+			},
+
+			"use_cli_credentials": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ARM_USE_CLI", false),
+				Description: "Authenticate using the tokens cached by an `az login` session (~/.azure/accessTokens.json, with the default subscription from ~/.azure/azureProfile.json), instead of a service principal.",
+			},
+
+			"environment": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ARM_ENVIRONMENT", "public"),
+				ValidateFunc: func(v interface{}, k string) (ws []string, es []error) {
+					value := strings.ToLower(v.(string))
+					if _, err := azureEnvironmentByName(value); err != nil {
+						es = append(es, err)
+					}
+					return
+				},
+			},
+
+			"poll_timeout": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     3600,
+				Description: "The maximum number of seconds to poll a long-running ARM operation before giving up.",
+			},
+
+			"poll_interval": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     5,
+				Description: "The base number of seconds to wait between polls of a long-running ARM operation.",
+			},
+
+			"max_poll_attempts": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: "The maximum number of times to poll a long-running ARM operation before giving up. 0 means no limit beyond poll_timeout.",
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
@@ -55,6 +110,9 @@ func Provider() terraform.ResourceProvider {
 			"azurerm_route":                  resourceArmRoute(),
 			"azurerm_cdn_profile":            resourceArmCdnProfile(),
 			"azurerm_cdn_endpoint":           resourceArmCdnEndpoint(),
+			"azurerm_storage_container":      resourceArmStorageContainer(),
+			"azurerm_storage_blob":           resourceArmStorageBlob(),
+			"azurerm_virtual_machine":        resourceArmVirtualMachine(),
 		},
 		ConfigureFunc: providerConfigure,
 	}
@@ -69,6 +127,14 @@ type Config struct {
 	ClientID       string
 	ClientSecret   string
 	TenantID       string
+
+	UseMsi            bool
+	UseCliCredentials bool
+	Environment       string
+
+	PollTimeout     time.Duration
+	PollInterval    time.Duration
+	MaxPollAttempts int
 }
 
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
@@ -77,8 +143,23 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		ClientID:       d.Get("client_id").(string),
 		ClientSecret:   d.Get("client_secret").(string),
 		TenantID:       d.Get("tenant_id").(string),
+
+		UseMsi:            d.Get("use_msi").(bool),
+		UseCliCredentials: d.Get("use_cli_credentials").(bool),
+		Environment:       strings.ToLower(d.Get("environment").(string)),
+
+		PollTimeout:     time.Duration(d.Get("poll_timeout").(int)) * time.Second,
+		PollInterval:    time.Duration(d.Get("poll_interval").(int)) * time.Second,
+		MaxPollAttempts: d.Get("max_poll_attempts").(int),
+	}
+
+	if err := config.validateAuthFields(); err != nil {
+		return nil, err
 	}
 
+	// config.getArmClient builds the Authorizer via config.buildAuthorizer, which picks
+	// between MSI, CLI-cached tokens and client credentials based on UseMsi/UseCliCredentials,
+	// and resolves endpoints from config.Environment rather than assuming AzurePublicCloud.
 	client, err := config.getArmClient()
 	if err != nil {
 		return nil, err
@@ -92,6 +173,60 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	return client, nil
 }
 
+// validateAuthFields enforces which credential fields are required for the selected auth
+// mode: MSI needs none of the service-principal fields, CLI mode only needs a subscription
+// to select (falling back to the CLI's current subscription), and the client-credentials
+// default requires all four of subscription_id/client_id/client_secret/tenant_id.
+func (c *Config) validateAuthFields() error {
+	if c.UseMsi && c.UseCliCredentials {
+		return fmt.Errorf("use_msi and use_cli_credentials are mutually exclusive")
+	}
+
+	if c.UseMsi || c.UseCliCredentials {
+		return nil
+	}
+
+	missing := []string{}
+	if c.SubscriptionID == "" {
+		missing = append(missing, "subscription_id")
+	}
+	if c.ClientID == "" {
+		missing = append(missing, "client_id")
+	}
+	if c.ClientSecret == "" {
+		missing = append(missing, "client_secret")
+	}
+	if c.TenantID == "" {
+		missing = append(missing, "tenant_id")
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%s must be set unless use_msi or use_cli_credentials is enabled", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// armEnvironmentNames maps the short names accepted by the "environment" provider argument
+// to the azure.Environment names registered by go-autorest/autorest/azure.
+var armEnvironmentNames = map[string]string{
+	"public":       "AzurePublicCloud",
+	"usgovernment": "AzureUSGovernmentCloud",
+	"german":       "AzureGermanCloud",
+	"china":        "AzureChinaCloud",
+}
+
+// azureEnvironmentByName resolves one of the short "environment" argument values to the
+// azure.Environment carrying its ARM/Active Directory/Storage endpoints.
+func azureEnvironmentByName(name string) (azure.Environment, error) {
+	sdkName, ok := armEnvironmentNames[strings.ToLower(name)]
+	if !ok {
+		return azure.Environment{}, fmt.Errorf("Invalid environment %q: must be one of \"public\", \"usgovernment\", \"german\" or \"china\"", name)
+	}
+
+	return azure.EnvironmentFromName(sdkName)
+}
+
 // registerAzureResourceProvidersWithSubscription uses the providers client to register
 // all Azure resource providers which the Terraform provider may require (regardless of
 // whether they are actually used by the configuration or not). It was confirmed by Microsoft
@@ -115,6 +250,52 @@ func registerAzureResourceProvidersWithSubscription(config *Config, client *ArmC
 	return nil
 }
 
+// buildAuthorizer picks an autorest.Authorizer for the configured auth mode: MSI via the
+// VM's IMDS endpoint, the token `az login` cached in ~/.azure/accessTokens.json (with the
+// default subscription and tenant read from ~/.azure/azureProfile.json), or the
+// client-credentials flow against c.Environment's Active Directory endpoint. getArmClient
+// calls this instead of always building a client-credentials token, and uses the returned
+// azure.Environment for the Resource Manager endpoint of every ARM client and, through
+// ArmClient.environment, for the storage endpoint suffix. No Graph endpoint is resolved;
+// the provider builds no Graph client.
+func (c *Config) buildAuthorizer() (autorest.Authorizer, azure.Environment, error) {
+	env, err := azureEnvironmentByName(c.Environment)
+	if err != nil {
+		return nil, azure.Environment{}, err
+	}
+
+	switch {
+	case c.UseMsi:
+		spt, err := azure.NewServicePrincipalTokenFromMSI(msiEndpoint, env.ResourceManagerEndpoint)
+		if err != nil {
+			return nil, env, fmt.Errorf("Error authenticating via MSI: %s", err)
+		}
+		return autorest.NewBearerAuthorizer(spt), env, nil
+
+	case c.UseCliCredentials:
+		authorizer, subscriptionID, _, err := authorizerFromAzureCliProfile(env)
+		if err != nil {
+			return nil, env, fmt.Errorf("Error authenticating via Azure CLI profile: %s", err)
+		}
+		if c.SubscriptionID == "" {
+			c.SubscriptionID = subscriptionID
+		}
+		return authorizer, env, nil
+
+	default:
+		oauthConfig, err := env.OAuthConfigForTenant(c.TenantID)
+		if err != nil {
+			return nil, env, fmt.Errorf("Error building OAuth config for tenant %q: %s", c.TenantID, err)
+		}
+
+		spt, err := azure.NewServicePrincipalToken(*oauthConfig, c.ClientID, c.ClientSecret, env.ResourceManagerEndpoint)
+		if err != nil {
+			return nil, env, fmt.Errorf("Error authenticating via client credentials: %s", err)
+		}
+		return autorest.NewBearerAuthorizer(spt), env, nil
+	}
+}
+
 // azureRMNormalizeLocation is a function which normalises human-readable region/location
 // names (e.g. "West US") to the values used and returned by the Azure API (e.g. "westus").
 // In state we track the API internal version as it is easier to go from the human form
@@ -124,37 +305,102 @@ func azureRMNormalizeLocation(location interface{}) string {
 	return strings.Replace(strings.ToLower(input), " ", "", -1)
 }
 
-// pollIndefinitelyAsNeeded is a terrible hack which is necessary because the Azure
-// Storage API (and perhaps others) can have response times way beyond the default
-// retry timeouts, with no apparent upper bound. This effectively causes the client
-// to continue polling when it reaches the configured timeout. My investigations
-// suggest that this is neccesary when deleting and recreating a storage account with
-// the same name in a short (though undetermined) time period.
-//
-// It is possible that this will give Terraform the appearance of being slow in
-// future: I have attempted to mitigate this by logging whenever this happens. We
-// may want to revisit this with configurable timeouts in the future as clearly
-// unbounded wait loops is not ideal. It does seem preferable to the current situation
-// where our polling loop will time out _with an operation in progress_, but no ID
-// for the resource - so the state will not know about it, and conflicts will occur
-// on the next run.
-func pollIndefinitelyAsNeeded(client autorest.Client, response *http.Response, acceptableCodes ...int) (*http.Response, error) {
+// defaultPollTimeout, defaultPollInterval and defaultMaxPollAttempts seed pollSettings when
+// a *Config is built outside of providerConfigure (e.g. in a test), so pollIndefinitelyAsNeeded
+// is still bounded even then.
+const (
+	defaultPollTimeout     = 60 * time.Minute
+	defaultPollInterval    = 5 * time.Second
+	defaultMaxPollAttempts = 0
+)
+
+// pollSettings holds the bounds pollIndefinitelyAsNeeded enforces. It is populated from the
+// "poll_timeout", "poll_interval" and "max_poll_attempts" schema fields in providerConfigure,
+// stored on ArmClient by getArmClient, and passed explicitly into pollIndefinitelyAsNeeded by
+// every call site rather than read from shared state.
+type pollSettings struct {
+	timeout     time.Duration
+	interval    time.Duration
+	maxAttempts int
+}
+
+var defaultPollSettings = pollSettings{
+	timeout:     defaultPollTimeout,
+	interval:    defaultPollInterval,
+	maxAttempts: defaultMaxPollAttempts,
+}
+
+// pollTimeoutError is returned by pollIndefinitelyAsNeeded when the configured deadline
+// or attempt cap is reached with the ARM operation still in progress.
+type pollTimeoutError struct {
+	path     string
+	attempts int
+	timeout  time.Duration
+}
+
+func (e *pollTimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %d attempt(s) (%s) polling %q", e.attempts, e.timeout, e.path)
+}
+
+// pollIndefinitelyAsNeeded used to be a terrible hack: it looped forever because the Azure
+// Storage API (and perhaps others) can have response times way beyond the default retry
+// timeouts, with no apparent upper bound. It now still retries past autorest's own
+// PollAsNeeded timeout, but is bounded by the caller-supplied settings (sourced from the
+// provider's "poll_timeout"/"max_poll_attempts" via ArmClient.poll) and backs off
+// exponentially (capped, with jitter) between attempts, so a stuck operation now surfaces
+// as a typed error instead of hanging the run forever.
+func pollIndefinitelyAsNeeded(ctx context.Context, settings pollSettings, client autorest.Client, response *http.Response, acceptableCodes ...int) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, settings.timeout)
+	defer cancel()
+
 	var resp *http.Response
 	var err error
+	attempt := 0
 
 	for {
 		resp, err = client.PollAsNeeded(response, acceptableCodes...)
-		if err != nil {
-			if resp.StatusCode != http.StatusAccepted {
-				log.Printf("[DEBUG] Starting new polling loop for %q", response.Request.URL.Path)
-				continue
-			}
+		if err == nil {
+			return resp, nil
+		}
 
+		if resp.StatusCode != http.StatusAccepted {
 			return resp, err
 		}
 
-		return resp, nil
+		attempt++
+		if settings.maxAttempts > 0 && attempt >= settings.maxAttempts {
+			return resp, &pollTimeoutError{path: response.Request.URL.Path, attempts: attempt, timeout: settings.timeout}
+		}
+
+		wait := backoffDuration(settings.interval, attempt)
+
+		log.Printf("[DEBUG] Still waiting on %q, backing off for %s (attempt %d)", response.Request.URL.Path, wait, attempt)
+
+		select {
+		case <-ctx.Done():
+			return resp, &pollTimeoutError{path: response.Request.URL.Path, attempts: attempt, timeout: settings.timeout}
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoffDuration computes an exponential backoff (base, 2x base, 4x base, ...), capped at
+// 10x the base interval, and jittered by up to 50% to avoid every resource in a large plan
+// retrying in lockstep.
+func backoffDuration(base time.Duration, attempt int) time.Duration {
+	maxWait := base * 10
+
+	shift := uint(attempt - 1)
+	if shift > 4 {
+		shift = 4 // base<<4 == 16x base, already past the cap below
 	}
+	capped := base << shift
+	if capped > maxWait {
+		capped = maxWait
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(capped)/2 + 1))
+	return capped + jitter
 }
 
 // armMutexKV is the instance of MutexKV for ARM resources
@@ -2,6 +2,7 @@ package azurerm
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -123,13 +124,38 @@ func resourceArmStorageAccount() *schema.Resource {
 				Computed: true,
 			},
 
+			"primary_access_key": &schema.Schema{
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+
+			"secondary_access_key": &schema.Schema{
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+
+			"primary_connection_string": &schema.Schema{
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+
+			"secondary_connection_string": &schema.Schema{
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+
 			"tags": tagsSchema(),
 		},
 	}
 }
 
 func resourceArmStorageAccountCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ArmClient).storageServiceClient
+	armClient := meta.(*ArmClient)
+	client := armClient.storageServiceClient
 
 	resourceGroupName := d.Get("resource_group_name").(string)
 	storageAccountName := d.Get("name").(string)
@@ -149,7 +175,7 @@ func resourceArmStorageAccountCreate(d *schema.ResourceData, meta interface{}) e
 	if err != nil {
 		return fmt.Errorf("Error creating Azure Storage Account '%s': %s", storageAccountName, err)
 	}
-	_, err = pollIndefinitelyAsNeeded(client.Client, accResp.Response.Response, http.StatusOK)
+	_, err = pollIndefinitelyAsNeeded(context.Background(), armClient.poll, client.Client, accResp.Response.Response, http.StatusOK)
 	if err != nil {
 		return fmt.Errorf("Error creating Azure Storage Account %q: %s", storageAccountName, err)
 	}
@@ -169,11 +195,84 @@ func resourceArmStorageAccountCreate(d *schema.ResourceData, meta interface{}) e
 // and idempotent operation for CreateOrUpdate. In particular updating all of the parameters
 // available requires a call to Update per parameter...
 func resourceArmStorageAccountUpdate(d *schema.ResourceData, meta interface{}) error {
-	return nil
+	armClient := meta.(*ArmClient)
+	client := armClient.storageServiceClient
+
+	id, err := parseAzureResourceID(d.Id())
+	if err != nil {
+		return err
+	}
+	name := id.Path["storageAccounts"]
+	resGroup := id.ResourceGroup
+
+	d.Partial(true)
+
+	if d.HasChange("account_type") {
+		accountType := d.Get("account_type").(string)
+
+		opts := storage.AccountUpdateParameters{
+			Properties: &storage.AccountPropertiesUpdateParameters{
+				AccountType: storage.AccountType(accountType),
+			},
+		}
+		accResp, err := client.Update(resGroup, name, opts)
+		if err != nil {
+			return fmt.Errorf("Error updating Azure Storage Account account_type %q: %s", name, err)
+		}
+		_, err = pollIndefinitelyAsNeeded(context.Background(), armClient.poll, client.Client, accResp.Response.Response, http.StatusOK)
+		if err != nil {
+			return fmt.Errorf("Error updating Azure Storage Account account_type %q: %s", name, err)
+		}
+
+		d.SetPartial("account_type")
+	}
+
+	if d.HasChange("tags") {
+		tags := d.Get("tags").(map[string]interface{})
+
+		opts := storage.AccountUpdateParameters{
+			Tags: expandTags(tags),
+		}
+		accResp, err := client.Update(resGroup, name, opts)
+		if err != nil {
+			return fmt.Errorf("Error updating Azure Storage Account tags %q: %s", name, err)
+		}
+		_, err = pollIndefinitelyAsNeeded(context.Background(), armClient.poll, client.Client, accResp.Response.Response, http.StatusOK)
+		if err != nil {
+			return fmt.Errorf("Error updating Azure Storage Account tags %q: %s", name, err)
+		}
+
+		d.SetPartial("tags")
+	}
+
+	if d.HasChange("custom_domain") {
+		customDomain := expandStorageAccountCustomDomain(d)
+
+		opts := storage.AccountUpdateParameters{
+			Properties: &storage.AccountPropertiesUpdateParameters{
+				CustomDomain: customDomain,
+			},
+		}
+		accResp, err := client.Update(resGroup, name, opts)
+		if err != nil {
+			return fmt.Errorf("Error updating Azure Storage Account custom_domain %q: %s", name, err)
+		}
+		_, err = pollIndefinitelyAsNeeded(context.Background(), armClient.poll, client.Client, accResp.Response.Response, http.StatusOK)
+		if err != nil {
+			return fmt.Errorf("Error updating Azure Storage Account custom_domain %q: %s", name, err)
+		}
+
+		d.SetPartial("custom_domain")
+	}
+
+	d.Partial(false)
+
+	return resourceArmStorageAccountRead(d, meta)
 }
 
 func resourceArmStorageAccountRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ArmClient).storageServiceClient
+	armClient := meta.(*ArmClient)
+	client := armClient.storageServiceClient
 
 	id, err := parseAzureResourceID(d.Id())
 	if err != nil {
@@ -218,11 +317,39 @@ func resourceArmStorageAccountRead(d *schema.ResourceData, meta interface{}) err
 		d.Set("custom_domain", customDomain)
 	}
 
+	keys, err := client.ListKeys(resGroup, name)
+	if err != nil {
+		return fmt.Errorf("Error listing keys for Azure Storage Account %q: %s", name, err)
+	}
+
+	var primaryKey, secondaryKey string
+	if keys.Key1 != nil {
+		primaryKey = *keys.Key1
+	}
+	if keys.Key2 != nil {
+		secondaryKey = *keys.Key2
+	}
+
+	d.Set("primary_access_key", primaryKey)
+	d.Set("secondary_access_key", secondaryKey)
+	d.Set("primary_connection_string", storageAccountConnectionString(name, primaryKey, armClient.environment.StorageEndpointSuffix))
+	d.Set("secondary_connection_string", storageAccountConnectionString(name, secondaryKey, armClient.environment.StorageEndpointSuffix))
+
 	flattenAndSetTags(d, resp.Tags)
 
 	return nil
 }
 
+// storageAccountConnectionString builds the "DefaultEndpointsProtocol=..." connection
+// string Azure SDKs/tools expect, given a storage account name, one of its keys, and the
+// storage EndpointSuffix of the configured environment (e.g. "core.windows.net" for public
+// cloud, but a different suffix for usgovernment/german/china).
+func storageAccountConnectionString(accountName, accountKey, endpointSuffix string) string {
+	return fmt.Sprintf(
+		"DefaultEndpointsProtocol=https;AccountName=%s;AccountKey=%s;EndpointSuffix=%s",
+		accountName, accountKey, endpointSuffix)
+}
+
 func resourceArmStorageAccountDelete(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*ArmClient).storageServiceClient
 
@@ -241,6 +368,28 @@ func resourceArmStorageAccountDelete(d *schema.ResourceData, meta interface{}) e
 	return nil
 }
 
+// expandStorageAccountCustomDomain pulls the first (and only) entry out of the
+// "custom_domain" set and turns it into the struct the ARM Storage Update API expects.
+// An empty set clears the custom domain on the account.
+func expandStorageAccountCustomDomain(d *schema.ResourceData) *storage.CustomDomain {
+	domains := d.Get("custom_domain").(*schema.Set).List()
+	if len(domains) == 0 {
+		empty := ""
+		return &storage.CustomDomain{
+			Name: &empty,
+		}
+	}
+
+	domain := domains[0].(map[string]interface{})
+	name := domain["name"].(string)
+	useSubDomain := domain["use_subdomain"].(bool)
+
+	return &storage.CustomDomain{
+		Name:         &name,
+		UseSubDomain: &useSubDomain,
+	}
+}
+
 func validateArmStorageAccountName(v interface{}, k string) (ws []string, es []error) {
 	input := v.(string)
 
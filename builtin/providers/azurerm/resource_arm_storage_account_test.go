@@ -1,6 +1,169 @@
 package azurerm
 
-import "testing"
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// testAccAzureRMStorageAccountCustomDomainHash mirrors the Set function on the
+// "custom_domain" TypeSet in resource_arm_storage_account.go, so tests can address a
+// specific element of the set by its hash key instead of a stable index.
+func testAccAzureRMStorageAccountCustomDomainHash(name string, useSubdomain bool) int {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%s-", name))
+	buf.WriteString(fmt.Sprintf("%t-", useSubdomain))
+	return hashcode.String(buf.String())
+}
+
+func TestStorageAccountConnectionString(t *testing.T) {
+	expected := "DefaultEndpointsProtocol=https;AccountName=testacc;AccountKey=abc123;EndpointSuffix=core.windows.net"
+	actual := storageAccountConnectionString("testacc", "abc123", "core.windows.net")
+
+	if actual != expected {
+		t.Fatalf("Expected connection string %q, got %q", expected, actual)
+	}
+}
+
+func TestAccAzureRMStorageAccount_update(t *testing.T) {
+	ri := acctest.RandInt()
+	preConfig := testAccAzureRMStorageAccount_basic(ri)
+	postConfig := testAccAzureRMStorageAccount_update(ri)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckAzureRMStorageAccountDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: preConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckAzureRMStorageAccountExists("azurerm_storage_account.testsa"),
+					resource.TestCheckResourceAttr(
+						"azurerm_storage_account.testsa", "account_type", "Standard_LRS"),
+				),
+			},
+
+			resource.TestStep{
+				Config: postConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckAzureRMStorageAccountExists("azurerm_storage_account.testsa"),
+					resource.TestCheckResourceAttr(
+						"azurerm_storage_account.testsa", "account_type", "Standard_GRS"),
+					resource.TestCheckResourceAttr(
+						"azurerm_storage_account.testsa", "tags.environment", "production"),
+					resource.TestCheckResourceAttr(
+						"azurerm_storage_account.testsa",
+						fmt.Sprintf("custom_domain.%d.name", testAccAzureRMStorageAccountCustomDomainHash("tf-testing.example.com", false)),
+						"tf-testing.example.com"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckAzureRMStorageAccountExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		storageAccountName := rs.Primary.Attributes["name"]
+		resourceGroup, ok := rs.Primary.Attributes["resource_group_name"]
+		if !ok {
+			return fmt.Errorf("Bad: no resource group found in state for storage account: %s", storageAccountName)
+		}
+
+		conn := testAccProvider.Meta().(*ArmClient).storageServiceClient
+
+		resp, err := conn.GetProperties(resourceGroup, storageAccountName)
+		if err != nil {
+			return fmt.Errorf("Bad: GetProperties on storageServiceClient: %s", err)
+		}
+
+		if resp.StatusCode == 404 {
+			return fmt.Errorf("Bad: Storage Account %q (resource group: %q) does not exist", storageAccountName, resourceGroup)
+		}
+
+		return nil
+	}
+}
+
+func testCheckAzureRMStorageAccountDestroy(s *terraform.State) error {
+	conn := testAccProvider.Meta().(*ArmClient).storageServiceClient
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "azurerm_storage_account" {
+			continue
+		}
+
+		storageAccountName := rs.Primary.Attributes["name"]
+		resourceGroup := rs.Primary.Attributes["resource_group_name"]
+
+		resp, err := conn.GetProperties(resourceGroup, storageAccountName)
+		if err != nil {
+			return nil
+		}
+
+		if resp.StatusCode != 404 {
+			return fmt.Errorf("Storage Account still exists: %q", storageAccountName)
+		}
+	}
+
+	return nil
+}
+
+func testAccAzureRMStorageAccount_basic(rInt int) string {
+	return fmt.Sprintf(`
+resource "azurerm_resource_group" "testrg" {
+    name = "acctestAzureRMSA-%d"
+    location = "West US"
+}
+
+resource "azurerm_storage_account" "testsa" {
+    name = "unlikely23exst2acct%d"
+    resource_group_name = "${azurerm_resource_group.testrg.name}"
+
+    location = "West US"
+    account_type = "Standard_LRS"
+
+    tags {
+        environment = "staging"
+    }
+}
+`, rInt, rInt)
+}
+
+func testAccAzureRMStorageAccount_update(rInt int) string {
+	return fmt.Sprintf(`
+resource "azurerm_resource_group" "testrg" {
+    name = "acctestAzureRMSA-%d"
+    location = "West US"
+}
+
+resource "azurerm_storage_account" "testsa" {
+    name = "unlikely23exst2acct%d"
+    resource_group_name = "${azurerm_resource_group.testrg.name}"
+
+    location = "West US"
+    account_type = "Standard_GRS"
+
+    custom_domain {
+        name = "tf-testing.example.com"
+    }
+
+    tags {
+        environment = "production"
+    }
+}
+`, rInt, rInt)
+}
 
 func TestValidateArmStorageAccountType(t *testing.T) {
 	testCases := []struct {
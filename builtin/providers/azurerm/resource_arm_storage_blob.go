@@ -0,0 +1,229 @@
+package azurerm
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/storage"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceArmStorageBlob() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceArmStorageBlobCreate,
+		Read:   resourceArmStorageBlobRead,
+		Delete: resourceArmStorageBlobDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"resource_group_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"storage_account_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"storage_container_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"type": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: func(v interface{}, k string) (ws []string, es []error) {
+					value := strings.ToLower(v.(string))
+					if value != "block" && value != "page" {
+						es = append(es, fmt.Errorf("%q must be either \"block\" or \"page\"", k))
+					}
+					return
+				},
+			},
+
+			"size": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"content_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"source": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"source_uri": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func resourceArmStorageBlobCreate(d *schema.ResourceData, meta interface{}) error {
+	armClient := meta.(*ArmClient)
+
+	resourceGroupName := d.Get("resource_group_name").(string)
+	storageAccountName := d.Get("storage_account_name").(string)
+	containerName := d.Get("storage_container_name").(string)
+	name := d.Get("name").(string)
+	blobType := d.Get("type").(string)
+
+	blobClient, accountExists, err := armClient.getBlobStorageClientForStorageAccount(resourceGroupName, storageAccountName)
+	if err != nil {
+		return err
+	}
+	if !accountExists {
+		return fmt.Errorf("Storage Account %q (Resource Group %q) does not exist", storageAccountName, resourceGroupName)
+	}
+
+	container := blobClient.GetContainerReference(containerName)
+	blob := container.GetBlobReference(name)
+
+	if v, ok := d.GetOk("content_type"); ok {
+		blob.Properties.ContentType = v.(string)
+	}
+
+	source, sourceOk := d.GetOk("source")
+	sourceURI, sourceURIOk := d.GetOk("source_uri")
+
+	armMutexKV.Lock(storageAccountName)
+	defer armMutexKV.Unlock(storageAccountName)
+
+	switch strings.ToLower(blobType) {
+	case "block":
+		if sourceOk {
+			if err := resourceArmStorageBlobUploadFromSource(blob, source.(string)); err != nil {
+				return fmt.Errorf("Error uploading source file for blob %q: %s", name, err)
+			}
+		} else if sourceURIOk {
+			if err := resourceArmStorageBlobCopyFromURI(blob, sourceURI.(string)); err != nil {
+				return fmt.Errorf("Error copying source_uri for blob %q: %s", name, err)
+			}
+		} else {
+			if err := blob.CreateBlockBlob(nil); err != nil {
+				return fmt.Errorf("Error creating block blob %q: %s", name, err)
+			}
+		}
+	case "page":
+		size := int64(d.Get("size").(int))
+		if size%512 != 0 {
+			return fmt.Errorf("Page blobs must have a size that is a multiple of 512 bytes, got %d", size)
+		}
+		blob.Properties.ContentLength = size
+		if err := blob.PutPageBlob(nil); err != nil {
+			return fmt.Errorf("Error creating page blob %q: %s", name, err)
+		}
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s/%s", storageAccountName, containerName, name))
+
+	return resourceArmStorageBlobRead(d, meta)
+}
+
+func resourceArmStorageBlobUploadFromSource(blob *storage.Blob, source string) error {
+	file, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return blob.CreateBlockBlobFromReader(file, nil)
+}
+
+func resourceArmStorageBlobCopyFromURI(blob *storage.Blob, sourceURI string) error {
+	if _, err := url.ParseRequestURI(sourceURI); err != nil {
+		return fmt.Errorf("source_uri %q is not a valid URI: %s", sourceURI, err)
+	}
+
+	return blob.Copy(sourceURI, nil)
+}
+
+func resourceArmStorageBlobRead(d *schema.ResourceData, meta interface{}) error {
+	armClient := meta.(*ArmClient)
+
+	resourceGroupName := d.Get("resource_group_name").(string)
+	storageAccountName := d.Get("storage_account_name").(string)
+	containerName := d.Get("storage_container_name").(string)
+	name := d.Get("name").(string)
+
+	blobClient, accountExists, err := armClient.getBlobStorageClientForStorageAccount(resourceGroupName, storageAccountName)
+	if err != nil {
+		return err
+	}
+	if !accountExists {
+		log.Printf("[INFO] Storage Account %q doesn't exist so the blob won't exist", storageAccountName)
+		d.SetId("")
+		return nil
+	}
+
+	container := blobClient.GetContainerReference(containerName)
+	blob := container.GetBlobReference(name)
+
+	exists, err := blob.Exists()
+	if err != nil {
+		return fmt.Errorf("Error testing existence of blob %q: %s", name, err)
+	}
+
+	if !exists {
+		log.Printf("[INFO] Blob %q no longer exists, removing from state", name)
+		d.SetId("")
+		return nil
+	}
+
+	return nil
+}
+
+func resourceArmStorageBlobDelete(d *schema.ResourceData, meta interface{}) error {
+	armClient := meta.(*ArmClient)
+
+	resourceGroupName := d.Get("resource_group_name").(string)
+	storageAccountName := d.Get("storage_account_name").(string)
+	containerName := d.Get("storage_container_name").(string)
+	name := d.Get("name").(string)
+
+	blobClient, accountExists, err := armClient.getBlobStorageClientForStorageAccount(resourceGroupName, storageAccountName)
+	if err != nil {
+		return err
+	}
+	if !accountExists {
+		log.Printf("[INFO] Storage Account %q doesn't exist so the blob won't exist", storageAccountName)
+		return nil
+	}
+
+	armMutexKV.Lock(storageAccountName)
+	defer armMutexKV.Unlock(storageAccountName)
+
+	container := blobClient.GetContainerReference(containerName)
+	blob := container.GetBlobReference(name)
+
+	if _, err := blob.DeleteIfExists(nil); err != nil {
+		return fmt.Errorf("Error deleting storage blob %q: %s", name, err)
+	}
+
+	d.SetId("")
+	return nil
+}
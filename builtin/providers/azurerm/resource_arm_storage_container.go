@@ -0,0 +1,160 @@
+package azurerm
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/storage"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+var (
+	storageContainerResourceName = "azurerm_storage_container"
+)
+
+func resourceArmStorageContainer() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceArmStorageContainerCreate,
+		Read:   resourceArmStorageContainerRead,
+		Delete: resourceArmStorageContainerDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"resource_group_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"storage_account_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"container_access_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: func(v interface{}, k string) (ws []string, es []error) {
+					value := strings.ToLower(v.(string))
+					if value != "private" && value != "blob" && value != "container" {
+						es = append(es, fmt.Errorf("%q must be one of \"private\", \"blob\" or \"container\"", k))
+					}
+					return
+				},
+			},
+
+			"properties": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceArmStorageContainerCreate(d *schema.ResourceData, meta interface{}) error {
+	armClient := meta.(*ArmClient)
+
+	resourceGroupName := d.Get("resource_group_name").(string)
+	storageAccountName := d.Get("storage_account_name").(string)
+	name := d.Get("name").(string)
+	accessType := d.Get("container_access_type").(string)
+
+	blobClient, accountExists, err := armClient.getBlobStorageClientForStorageAccount(resourceGroupName, storageAccountName)
+	if err != nil {
+		return err
+	}
+	if !accountExists {
+		return fmt.Errorf("Storage Account %q (Resource Group %q) does not exist", storageAccountName, resourceGroupName)
+	}
+
+	log.Printf("[INFO] Creating storage container %q in storage account %q", name, storageAccountName)
+	container := blobClient.GetContainerReference(name)
+	// Private access is expressed by omitting the public-access level altogether.
+	perm := storage.ContainerAccessType(strings.ToLower(accessType))
+	if perm == "private" {
+		perm = storage.ContainerAccessType("")
+	}
+
+	armMutexKV.Lock(storageAccountName)
+	defer armMutexKV.Unlock(storageAccountName)
+
+	options := &storage.CreateContainerOptions{
+		Access: perm,
+	}
+	_, err = container.CreateIfNotExists(options)
+	if err != nil {
+		return fmt.Errorf("Error creating container %q in storage account %q: %s", name, storageAccountName, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", storageAccountName, name))
+
+	return resourceArmStorageContainerRead(d, meta)
+}
+
+func resourceArmStorageContainerRead(d *schema.ResourceData, meta interface{}) error {
+	armClient := meta.(*ArmClient)
+
+	resourceGroupName := d.Get("resource_group_name").(string)
+	storageAccountName := d.Get("storage_account_name").(string)
+	name := d.Get("name").(string)
+
+	blobClient, accountExists, err := armClient.getBlobStorageClientForStorageAccount(resourceGroupName, storageAccountName)
+	if err != nil {
+		return err
+	}
+	if !accountExists {
+		log.Printf("[INFO] Storage Account %q doesn't exist so the container won't exist", storageAccountName)
+		d.SetId("")
+		return nil
+	}
+
+	container := blobClient.GetContainerReference(name)
+	exists, err := container.Exists()
+	if err != nil {
+		return fmt.Errorf("Error testing existence of container %q in storage account %q: %s", name, storageAccountName, err)
+	}
+
+	if !exists {
+		log.Printf("[INFO] Container %q no longer exists, removing from state", name)
+		d.SetId("")
+		return nil
+	}
+
+	return nil
+}
+
+func resourceArmStorageContainerDelete(d *schema.ResourceData, meta interface{}) error {
+	armClient := meta.(*ArmClient)
+
+	resourceGroupName := d.Get("resource_group_name").(string)
+	storageAccountName := d.Get("storage_account_name").(string)
+	name := d.Get("name").(string)
+
+	blobClient, accountExists, err := armClient.getBlobStorageClientForStorageAccount(resourceGroupName, storageAccountName)
+	if err != nil {
+		return err
+	}
+	if !accountExists {
+		log.Printf("[INFO] Storage Account %q doesn't exist so the container won't exist", storageAccountName)
+		return nil
+	}
+
+	armMutexKV.Lock(storageAccountName)
+	defer armMutexKV.Unlock(storageAccountName)
+
+	container := blobClient.GetContainerReference(name)
+	if _, err = container.DeleteIfExists(); err != nil {
+		return fmt.Errorf("Error deleting storage container %q from storage account %q: %s", name, storageAccountName, err)
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,118 @@
+package azurerm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccAzureRMStorageContainer_basic(t *testing.T) {
+	ri := acctest.RandInt()
+	config := testAccAzureRMStorageContainer_basic(ri)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckAzureRMStorageContainerDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckAzureRMStorageContainerExists("azurerm_storage_container.testsc"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckAzureRMStorageContainerExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		containerName := rs.Primary.Attributes["name"]
+		storageAccountName := rs.Primary.Attributes["storage_account_name"]
+		resourceGroup := rs.Primary.Attributes["resource_group_name"]
+
+		armClient := testAccProvider.Meta().(*ArmClient)
+		blobClient, accountExists, err := armClient.getBlobStorageClientForStorageAccount(resourceGroup, storageAccountName)
+		if err != nil {
+			return err
+		}
+		if !accountExists {
+			return fmt.Errorf("Bad: storage account %q does not exist", storageAccountName)
+		}
+
+		container := blobClient.GetContainerReference(containerName)
+		exists, err := container.Exists()
+		if err != nil {
+			return fmt.Errorf("Bad: error checking existence of container %q: %s", containerName, err)
+		}
+		if !exists {
+			return fmt.Errorf("Bad: container %q does not exist", containerName)
+		}
+
+		return nil
+	}
+}
+
+func testCheckAzureRMStorageContainerDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "azurerm_storage_container" {
+			continue
+		}
+
+		containerName := rs.Primary.Attributes["name"]
+		storageAccountName := rs.Primary.Attributes["storage_account_name"]
+		resourceGroup := rs.Primary.Attributes["resource_group_name"]
+
+		armClient := testAccProvider.Meta().(*ArmClient)
+		blobClient, accountExists, err := armClient.getBlobStorageClientForStorageAccount(resourceGroup, storageAccountName)
+		if err != nil {
+			return nil
+		}
+		if !accountExists {
+			continue
+		}
+
+		container := blobClient.GetContainerReference(containerName)
+		exists, err := container.Exists()
+		if err != nil {
+			return nil
+		}
+		if exists {
+			return fmt.Errorf("Storage Container still exists: %q", containerName)
+		}
+	}
+
+	return nil
+}
+
+func testAccAzureRMStorageContainer_basic(rInt int) string {
+	return fmt.Sprintf(`
+resource "azurerm_resource_group" "testrg" {
+    name = "acctestAzureRMSC-%d"
+    location = "West US"
+}
+
+resource "azurerm_storage_account" "testsa" {
+    name = "unlikely23exst2acct%d"
+    resource_group_name = "${azurerm_resource_group.testrg.name}"
+
+    location = "West US"
+    account_type = "Standard_LRS"
+}
+
+resource "azurerm_storage_container" "testsc" {
+    name = "acctestsc%d"
+    resource_group_name = "${azurerm_resource_group.testrg.name}"
+    storage_account_name = "${azurerm_storage_account.testsa.name}"
+    container_access_type = "private"
+}
+`, rInt, rInt, rInt)
+}
@@ -0,0 +1,734 @@
+package azurerm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceArmVirtualMachine() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceArmVirtualMachineCreate,
+		Read:   resourceArmVirtualMachineRead,
+		Delete: resourceArmVirtualMachineDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"resource_group_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"location": &schema.Schema{
+				Type:      schema.TypeString,
+				Required:  true,
+				ForceNew:  true,
+				StateFunc: azureRMNormalizeLocation,
+			},
+
+			"vm_size": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"availability_set_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Computed: true,
+			},
+
+			"network_interface_ids": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				ForceNew: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"storage_image_reference": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				ForceNew: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"publisher": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+						"offer": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+						"sku": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+						"version": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+					},
+				},
+			},
+
+			"storage_os_disk": &schema.Schema{
+				Type:     schema.TypeSet,
+				Required: true,
+				ForceNew: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+						"vhd_uri": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+						"create_option": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+						"caching": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+					},
+				},
+				Set: resourceArmVirtualMachineStorageOsDiskHash,
+			},
+
+			"storage_data_disk": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+						"vhd_uri": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+						"create_option": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+						"disk_size_gb": &schema.Schema{
+							Type:     schema.TypeInt,
+							Required: true,
+							ForceNew: true,
+						},
+						"lun": &schema.Schema{
+							Type:     schema.TypeInt,
+							Required: true,
+							ForceNew: true,
+						},
+						"caching": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+					},
+				},
+			},
+
+			"os_profile": &schema.Schema{
+				Type:     schema.TypeSet,
+				Required: true,
+				ForceNew: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"computer_name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+						"admin_username": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+						"admin_password": &schema.Schema{
+							Type:      schema.TypeString,
+							Optional:  true,
+							ForceNew:  true,
+							Sensitive: true,
+						},
+						"custom_data": &schema.Schema{
+							Type:      schema.TypeString,
+							Optional:  true,
+							ForceNew:  true,
+							StateFunc: resourceArmVirtualMachineStateCustomData,
+						},
+					},
+				},
+				Set: resourceArmVirtualMachineStorageOsProfileHash,
+			},
+
+			"os_profile_linux_config": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				ForceNew: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"disable_password_authentication": &schema.Schema{
+							Type:     schema.TypeBool,
+							Required: true,
+							ForceNew: true,
+						},
+						"ssh_keys": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"path": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+										ForceNew: true,
+									},
+									"key_data": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+										ForceNew: true,
+									},
+								},
+							},
+						},
+					},
+				},
+				Set: func(v interface{}) int {
+					m := v.(map[string]interface{})
+					return hashcode.String(fmt.Sprintf("%t", m["disable_password_authentication"].(bool)))
+				},
+			},
+
+			"os_profile_windows_config": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				ForceNew: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"provision_vm_agent": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							ForceNew: true,
+						},
+						"enable_automatic_upgrades": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							ForceNew: true,
+						},
+					},
+				},
+				Set: func(v interface{}) int {
+					m := v.(map[string]interface{})
+					return hashcode.String(fmt.Sprintf("%t-%t", m["provision_vm_agent"].(bool), m["enable_automatic_upgrades"].(bool)))
+				},
+			},
+
+			"tags": tagsSchema(),
+		},
+	}
+}
+
+// resourceArmVirtualMachineStateCustomData base64-encodes custom_data so that the value
+// Terraform tracks in state always matches what the API returns. It must run custom_data
+// through the same readCustomDataSource file-or-literal resolution as
+// expandAzureRMVirtualMachineOsProfile, or a file-backed custom_data would state the
+// base64 of the path while the API holds the base64 of the file's contents, causing a
+// permanent diff.
+func resourceArmVirtualMachineStateCustomData(v interface{}) string {
+	switch v.(type) {
+	case string:
+		customData, err := readCustomDataSource(v.(string))
+		if err != nil {
+			return ""
+		}
+		return base64Encode(customData)
+	default:
+		return ""
+	}
+}
+
+func base64Encode(data string) string {
+	return base64.StdEncoding.EncodeToString([]byte(data))
+}
+
+func resourceArmVirtualMachineStorageOsDiskHash(v interface{}) int {
+	var buf bytes.Buffer
+	m := v.(map[string]interface{})
+	buf.WriteString(fmt.Sprintf("%s-", m["name"].(string)))
+	return hashcode.String(buf.String())
+}
+
+func resourceArmVirtualMachineStorageOsProfileHash(v interface{}) int {
+	var buf bytes.Buffer
+	m := v.(map[string]interface{})
+	buf.WriteString(fmt.Sprintf("%s-", m["computer_name"].(string)))
+	buf.WriteString(fmt.Sprintf("%s-", m["admin_username"].(string)))
+	return hashcode.String(buf.String())
+}
+
+func resourceArmVirtualMachineCreate(d *schema.ResourceData, meta interface{}) error {
+	armClient := meta.(*ArmClient)
+	client := armClient.vmClient
+
+	resGroup := d.Get("resource_group_name").(string)
+	name := d.Get("name").(string)
+	location := d.Get("location").(string)
+	tags := d.Get("tags").(map[string]interface{})
+
+	osProfile, err := expandAzureRMVirtualMachineOsProfile(d)
+	if err != nil {
+		return err
+	}
+
+	storageProfile := compute.StorageProfile{
+		ImageReference: expandAzureRMVirtualMachineImageReference(d),
+		OsDisk:         expandAzureRMVirtualMachineOsDisk(d),
+		DataDisks:      expandAzureRMVirtualMachineDataDisk(d),
+	}
+
+	networkProfile := compute.NetworkProfile{}
+	nicsConfig := d.Get("network_interface_ids").([]interface{})
+	nics := make([]compute.NetworkInterfaceReference, 0, len(nicsConfig))
+	for _, nicID := range nicsConfig {
+		id := nicID.(string)
+		nics = append(nics, compute.NetworkInterfaceReference{
+			ID: &id,
+		})
+	}
+	networkProfile.NetworkInterfaces = &nics
+
+	vmSize := d.Get("vm_size").(string)
+
+	properties := compute.VirtualMachineProperties{
+		HardwareProfile: &compute.HardwareProfile{
+			VMSize: compute.VirtualMachineSizeTypes(vmSize),
+		},
+		OsProfile:      osProfile,
+		StorageProfile: &storageProfile,
+		NetworkProfile: &networkProfile,
+	}
+
+	if v, ok := d.GetOk("availability_set_id"); ok {
+		availabilitySetID := v.(string)
+		properties.AvailabilitySet = &compute.SubResource{
+			ID: &availabilitySetID,
+		}
+	}
+
+	vm := compute.VirtualMachine{
+		Name:       &name,
+		Location:   &location,
+		Properties: &properties,
+		Tags:       expandTags(tags),
+	}
+
+	resp, err := client.CreateOrUpdate(resGroup, name, vm)
+	if err != nil {
+		return fmt.Errorf("Error creating Azure Virtual Machine %q: %s", name, err)
+	}
+	_, err = pollIndefinitelyAsNeeded(context.Background(), armClient.poll, client.Client, resp.Response.Response, 200)
+	if err != nil {
+		return fmt.Errorf("Error creating Azure Virtual Machine %q: %s", name, err)
+	}
+
+	read, err := client.Get(resGroup, name, "")
+	if err != nil {
+		return fmt.Errorf("Error retrieving Azure Virtual Machine %q: %s", name, err)
+	}
+
+	d.SetId(*read.ID)
+
+	return resourceArmVirtualMachineRead(d, meta)
+}
+
+func resourceArmVirtualMachineRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*ArmClient).vmClient
+
+	id, err := parseAzureResourceID(d.Id())
+	if err != nil {
+		return err
+	}
+	resGroup := id.ResourceGroup
+	name := id.Path["virtualMachines"]
+
+	resp, err := client.Get(resGroup, name, "")
+	if err != nil {
+		if resp.StatusCode == 404 {
+			log.Printf("[INFO] Virtual Machine %q no longer exists, removing from state", name)
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Error reading the state of Azure Virtual Machine %q: %s", name, err)
+	}
+
+	d.Set("name", resp.Name)
+	d.Set("location", azureRMNormalizeLocation(*resp.Location))
+	d.Set("vm_size", string(resp.Properties.HardwareProfile.VMSize))
+
+	if resp.Properties.AvailabilitySet != nil {
+		d.Set("availability_set_id", *resp.Properties.AvailabilitySet.ID)
+	}
+
+	if resp.Properties.NetworkProfile != nil {
+		nics := make([]string, 0, len(*resp.Properties.NetworkProfile.NetworkInterfaces))
+		for _, nic := range *resp.Properties.NetworkProfile.NetworkInterfaces {
+			nics = append(nics, *nic.ID)
+		}
+		d.Set("network_interface_ids", nics)
+	}
+
+	if storageProfile := resp.Properties.StorageProfile; storageProfile != nil {
+		if err := d.Set("storage_image_reference", flattenAzureRMVirtualMachineImageReference(storageProfile.ImageReference)); err != nil {
+			return fmt.Errorf("Error flattening storage_image_reference for Azure Virtual Machine %q: %s", name, err)
+		}
+
+		if err := d.Set("storage_os_disk", flattenAzureRMVirtualMachineOsDisk(storageProfile.OsDisk)); err != nil {
+			return fmt.Errorf("Error flattening storage_os_disk for Azure Virtual Machine %q: %s", name, err)
+		}
+
+		if err := d.Set("storage_data_disk", flattenAzureRMVirtualMachineDataDisk(storageProfile.DataDisks)); err != nil {
+			return fmt.Errorf("Error flattening storage_data_disk for Azure Virtual Machine %q: %s", name, err)
+		}
+	}
+
+	// Only computer_name/admin_username come back from the API; admin_password and
+	// custom_data are write-only and are never returned by a GET, so they're carried
+	// forward from the existing state rather than cleared out here.
+	if osProfile := resp.Properties.OsProfile; osProfile != nil {
+		if err := d.Set("os_profile", flattenAzureRMVirtualMachineOsProfile(d, osProfile)); err != nil {
+			return fmt.Errorf("Error flattening os_profile for Azure Virtual Machine %q: %s", name, err)
+		}
+	}
+
+	flattenAndSetTags(d, resp.Tags)
+
+	return nil
+}
+
+// flattenAzureRMVirtualMachineImageReference turns the API's ImageReference into the
+// single-element list storage_image_reference expects.
+func flattenAzureRMVirtualMachineImageReference(imageRef *compute.ImageReference) []interface{} {
+	if imageRef == nil {
+		return []interface{}{}
+	}
+
+	result := make(map[string]interface{})
+	if imageRef.Publisher != nil {
+		result["publisher"] = *imageRef.Publisher
+	}
+	if imageRef.Offer != nil {
+		result["offer"] = *imageRef.Offer
+	}
+	if imageRef.Sku != nil {
+		result["sku"] = *imageRef.Sku
+	}
+	if imageRef.Version != nil {
+		result["version"] = *imageRef.Version
+	}
+
+	return []interface{}{result}
+}
+
+// flattenAzureRMVirtualMachineOsDisk turns the API's OSDisk into the single-element set
+// storage_os_disk expects, keyed by the same hash function the schema uses.
+func flattenAzureRMVirtualMachineOsDisk(osDisk *compute.OSDisk) *schema.Set {
+	result := &schema.Set{F: resourceArmVirtualMachineStorageOsDiskHash}
+	if osDisk == nil {
+		return result
+	}
+
+	disk := make(map[string]interface{})
+	if osDisk.Name != nil {
+		disk["name"] = *osDisk.Name
+	}
+	if osDisk.Vhd != nil && osDisk.Vhd.URI != nil {
+		disk["vhd_uri"] = *osDisk.Vhd.URI
+	}
+	disk["create_option"] = string(osDisk.CreateOption)
+	disk["caching"] = string(osDisk.Caching)
+
+	result.Add(disk)
+	return result
+}
+
+// flattenAzureRMVirtualMachineDataDisk turns the API's DataDisks into the list
+// storage_data_disk expects.
+func flattenAzureRMVirtualMachineDataDisk(disks *[]compute.DataDisk) []interface{} {
+	if disks == nil {
+		return []interface{}{}
+	}
+
+	result := make([]interface{}, 0, len(*disks))
+	for _, disk := range *disks {
+		m := make(map[string]interface{})
+		if disk.Name != nil {
+			m["name"] = *disk.Name
+		}
+		if disk.Vhd != nil && disk.Vhd.URI != nil {
+			m["vhd_uri"] = *disk.Vhd.URI
+		}
+		m["create_option"] = string(disk.CreateOption)
+		m["caching"] = string(disk.Caching)
+		if disk.Lun != nil {
+			m["lun"] = int(*disk.Lun)
+		}
+		if disk.DiskSizeGB != nil {
+			m["disk_size_gb"] = int(*disk.DiskSizeGB)
+		}
+		result = append(result, m)
+	}
+
+	return result
+}
+
+// flattenAzureRMVirtualMachineOsProfile turns the API's OSProfile into the single-element
+// set os_profile expects. admin_password and custom_data are preserved from the existing
+// state rather than read from the API response, since Azure never returns them on a GET.
+func flattenAzureRMVirtualMachineOsProfile(d *schema.ResourceData, osProfile *compute.OSProfile) *schema.Set {
+	result := &schema.Set{F: resourceArmVirtualMachineStorageOsProfileHash}
+
+	config := make(map[string]interface{})
+	if osProfile.ComputerName != nil {
+		config["computer_name"] = *osProfile.ComputerName
+	}
+	if osProfile.AdminUsername != nil {
+		config["admin_username"] = *osProfile.AdminUsername
+	}
+
+	if existing, ok := d.GetOk("os_profile"); ok {
+		for _, v := range existing.(*schema.Set).List() {
+			m := v.(map[string]interface{})
+			config["admin_password"] = m["admin_password"]
+			config["custom_data"] = m["custom_data"]
+		}
+	}
+
+	result.Add(config)
+	return result
+}
+
+func resourceArmVirtualMachineDelete(d *schema.ResourceData, meta interface{}) error {
+	armClient := meta.(*ArmClient)
+	client := armClient.vmClient
+
+	id, err := parseAzureResourceID(d.Id())
+	if err != nil {
+		return err
+	}
+	resGroup := id.ResourceGroup
+	name := id.Path["virtualMachines"]
+
+	resp, err := client.Delete(resGroup, name)
+	if err != nil {
+		return fmt.Errorf("Error issuing Azure Virtual Machine delete request for %q: %s", name, err)
+	}
+	_, err = pollIndefinitelyAsNeeded(context.Background(), armClient.poll, client.Client, resp.Response, 200, 202, 204)
+	if err != nil {
+		return fmt.Errorf("Error deleting Azure Virtual Machine %q: %s", name, err)
+	}
+
+	return nil
+}
+
+func expandAzureRMVirtualMachineImageReference(d *schema.ResourceData) *compute.ImageReference {
+	imageRefs := d.Get("storage_image_reference").([]interface{})
+	if len(imageRefs) == 0 {
+		return nil
+	}
+
+	imageRef := imageRefs[0].(map[string]interface{})
+
+	publisher := imageRef["publisher"].(string)
+	offer := imageRef["offer"].(string)
+	sku := imageRef["sku"].(string)
+	version := imageRef["version"].(string)
+
+	return &compute.ImageReference{
+		Publisher: &publisher,
+		Offer:     &offer,
+		Sku:       &sku,
+		Version:   &version,
+	}
+}
+
+func expandAzureRMVirtualMachineOsDisk(d *schema.ResourceData) *compute.OSDisk {
+	disks := d.Get("storage_os_disk").(*schema.Set).List()
+	disk := disks[0].(map[string]interface{})
+
+	name := disk["name"].(string)
+	vhdURI := disk["vhd_uri"].(string)
+	createOption := disk["create_option"].(string)
+
+	osDisk := &compute.OSDisk{
+		Name: &name,
+		Vhd: &compute.VirtualHardDisk{
+			URI: &vhdURI,
+		},
+		CreateOption: compute.DiskCreateOptionTypes(createOption),
+	}
+
+	if v, ok := disk["caching"].(string); ok && v != "" {
+		osDisk.Caching = compute.CachingTypes(v)
+	}
+
+	return osDisk
+}
+
+func expandAzureRMVirtualMachineDataDisk(d *schema.ResourceData) *[]compute.DataDisk {
+	disks := d.Get("storage_data_disk").([]interface{})
+	dataDisks := make([]compute.DataDisk, 0, len(disks))
+
+	for _, diskConfig := range disks {
+		config := diskConfig.(map[string]interface{})
+
+		name := config["name"].(string)
+		vhdURI := config["vhd_uri"].(string)
+		createOption := config["create_option"].(string)
+		lun := int32(config["lun"].(int))
+		diskSize := int32(config["disk_size_gb"].(int))
+
+		dataDisk := compute.DataDisk{
+			Name: &name,
+			Vhd: &compute.VirtualHardDisk{
+				URI: &vhdURI,
+			},
+			CreateOption: compute.DiskCreateOptionTypes(createOption),
+			Lun:          &lun,
+			DiskSizeGB:   &diskSize,
+		}
+
+		if v, ok := config["caching"].(string); ok && v != "" {
+			dataDisk.Caching = compute.CachingTypes(v)
+		}
+
+		dataDisks = append(dataDisks, dataDisk)
+	}
+
+	return &dataDisks
+}
+
+func expandAzureRMVirtualMachineOsProfile(d *schema.ResourceData) (*compute.OSProfile, error) {
+	osProfiles := d.Get("os_profile").(*schema.Set).List()
+	config := osProfiles[0].(map[string]interface{})
+
+	computerName := config["computer_name"].(string)
+	adminUsername := config["admin_username"].(string)
+
+	profile := &compute.OSProfile{
+		ComputerName:  &computerName,
+		AdminUsername: &adminUsername,
+	}
+
+	if v, ok := config["admin_password"].(string); ok && v != "" {
+		profile.AdminPassword = &v
+	}
+
+	if v, ok := config["custom_data"].(string); ok && v != "" {
+		customData, err := readCustomDataSource(v)
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64Encode(customData)
+		profile.CustomData = &encoded
+	}
+
+	if v, ok := d.GetOk("os_profile_linux_config"); ok {
+		linuxConfigs := v.(*schema.Set).List()
+		linuxConfig := linuxConfigs[0].(map[string]interface{})
+		disablePasswordAuth := linuxConfig["disable_password_authentication"].(bool)
+
+		config := &compute.LinuxConfiguration{
+			DisablePasswordAuthentication: &disablePasswordAuth,
+		}
+
+		if sshKeysRaw, ok := linuxConfig["ssh_keys"]; ok {
+			sshKeys := sshKeysRaw.([]interface{})
+			if len(sshKeys) > 0 {
+				publicKeys := make([]compute.SSHPublicKey, 0, len(sshKeys))
+				for _, keyRaw := range sshKeys {
+					key := keyRaw.(map[string]interface{})
+					path := key["path"].(string)
+					keyData := key["key_data"].(string)
+					publicKeys = append(publicKeys, compute.SSHPublicKey{
+						Path:    &path,
+						KeyData: &keyData,
+					})
+				}
+				config.SSH = &compute.SSHConfiguration{
+					PublicKeys: &publicKeys,
+				}
+			}
+		}
+
+		profile.LinuxConfiguration = config
+	}
+
+	if v, ok := d.GetOk("os_profile_windows_config"); ok {
+		windowsConfigs := v.(*schema.Set).List()
+		windowsConfig := windowsConfigs[0].(map[string]interface{})
+
+		config := &compute.WindowsConfiguration{}
+
+		if provisionVMAgent, ok := windowsConfig["provision_vm_agent"].(bool); ok {
+			config.ProvisionVMAgent = &provisionVMAgent
+		}
+		if enableAutoUpgrade, ok := windowsConfig["enable_automatic_upgrades"].(bool); ok {
+			config.EnableAutomaticUpdates = &enableAutoUpgrade
+		}
+
+		profile.WindowsConfiguration = config
+	}
+
+	return profile, nil
+}
+
+// readCustomDataSource treats the custom_data value as a path to a file when one exists on
+// disk, mirroring how other Terraform providers let users point at a script instead of
+// inlining it; otherwise the value is used verbatim as the custom_data content.
+func readCustomDataSource(value string) (string, error) {
+	if !strings.Contains(value, "\n") {
+		if contents, err := ioutil.ReadFile(value); err == nil {
+			return string(contents), nil
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,162 @@
+package azurerm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccAzureRMVirtualMachine_basicLinuxMachine(t *testing.T) {
+	ri := acctest.RandInt()
+	config := testAccAzureRMVirtualMachine_basicLinuxMachine(ri)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckAzureRMVirtualMachineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckAzureRMVirtualMachineExists("azurerm_virtual_machine.testvm"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckAzureRMVirtualMachineExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		vmName := rs.Primary.Attributes["name"]
+		resourceGroup, ok := rs.Primary.Attributes["resource_group_name"]
+		if !ok {
+			return fmt.Errorf("Bad: no resource group found in state for virtual machine: %s", vmName)
+		}
+
+		conn := testAccProvider.Meta().(*ArmClient).vmClient
+
+		resp, err := conn.Get(resourceGroup, vmName, "")
+		if err != nil {
+			return fmt.Errorf("Bad: Get on vmClient: %s", err)
+		}
+
+		if resp.StatusCode == 404 {
+			return fmt.Errorf("Bad: Virtual Machine %q (resource group: %q) does not exist", vmName, resourceGroup)
+		}
+
+		return nil
+	}
+}
+
+func testCheckAzureRMVirtualMachineDestroy(s *terraform.State) error {
+	conn := testAccProvider.Meta().(*ArmClient).vmClient
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "azurerm_virtual_machine" {
+			continue
+		}
+
+		vmName := rs.Primary.Attributes["name"]
+		resourceGroup := rs.Primary.Attributes["resource_group_name"]
+
+		resp, err := conn.Get(resourceGroup, vmName, "")
+		if err != nil {
+			return nil
+		}
+
+		if resp.StatusCode != 404 {
+			return fmt.Errorf("Virtual Machine still exists: %q", vmName)
+		}
+	}
+
+	return nil
+}
+
+func testAccAzureRMVirtualMachine_basicLinuxMachine(rInt int) string {
+	return fmt.Sprintf(`
+resource "azurerm_resource_group" "testrg" {
+    name = "acctestRG-%d"
+    location = "West US"
+}
+
+resource "azurerm_virtual_network" "testvn" {
+    name = "acctvn-%d"
+    address_space = ["10.0.0.0/16"]
+    location = "West US"
+    resource_group_name = "${azurerm_resource_group.testrg.name}"
+}
+
+resource "azurerm_subnet" "testsn" {
+    name = "acctsub-%d"
+    resource_group_name = "${azurerm_resource_group.testrg.name}"
+    virtual_network_name = "${azurerm_virtual_network.testvn.name}"
+    address_prefix = "10.0.2.0/24"
+}
+
+resource "azurerm_network_interface" "testni" {
+    name = "acctni-%d"
+    location = "West US"
+    resource_group_name = "${azurerm_resource_group.testrg.name}"
+
+    ip_configuration {
+    	name = "testconfiguration1"
+    	subnet_id = "${azurerm_subnet.testsn.id}"
+    	private_ip_address_allocation = "dynamic"
+    }
+}
+
+resource "azurerm_storage_account" "teststor" {
+    name = "accsa%d"
+    resource_group_name = "${azurerm_resource_group.testrg.name}"
+    location = "West US"
+    account_type = "Standard_LRS"
+}
+
+resource "azurerm_storage_container" "testsc" {
+    name = "vhds"
+    resource_group_name = "${azurerm_resource_group.testrg.name}"
+    storage_account_name = "${azurerm_storage_account.teststor.name}"
+    container_access_type = "private"
+}
+
+resource "azurerm_virtual_machine" "testvm" {
+    name = "acctvm-%d"
+    location = "West US"
+    resource_group_name = "${azurerm_resource_group.testrg.name}"
+    network_interface_ids = ["${azurerm_network_interface.testni.id}"]
+    vm_size = "Standard_A0"
+
+    storage_image_reference {
+    	publisher = "Canonical"
+    	offer = "UbuntuServer"
+    	sku = "14.04.2-LTS"
+    	version = "latest"
+    }
+
+    storage_os_disk {
+    	name = "myosdisk1"
+    	vhd_uri = "${azurerm_storage_account.teststor.primary_blob_endpoint}${azurerm_storage_container.testsc.name}/myosdisk1.vhd"
+    	caching = "ReadWrite"
+    	create_option = "FromImage"
+    }
+
+    os_profile {
+    	computer_name = "hn%d"
+    	admin_username = "testadmin"
+    	admin_password = "Password1234!"
+    }
+
+    os_profile_linux_config {
+    	disable_password_authentication = false
+    }
+}
+`, rInt, rInt, rInt, rInt, rInt, rInt, rInt)
+}
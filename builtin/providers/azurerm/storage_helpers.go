@@ -0,0 +1,40 @@
+package azurerm
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/storage"
+)
+
+// getBlobStorageClientForStorageAccount looks up the given storage account via the ARM
+// storageServiceClient, retrieves its primary access key, and uses it to build a data-plane
+// storage.BlobStorageClient against the configured environment's storage endpoint suffix. The bool return indicates whether the storage account was found;
+// callers use this to distinguish "account gone" from a hard error when reconciling state.
+func (armClient *ArmClient) getBlobStorageClientForStorageAccount(resourceGroupName, storageAccountName string) (*storage.BlobStorageClient, bool, error) {
+	client := armClient.storageServiceClient
+
+	armMutexKV.Lock(storageAccountName)
+	defer armMutexKV.Unlock(storageAccountName)
+
+	keysResp, err := client.ListKeys(resourceGroupName, storageAccountName)
+	if err != nil {
+		if keysResp.StatusCode == http.StatusNotFound {
+			return nil, false, nil
+		}
+		return nil, true, fmt.Errorf("Error retrieving keys for storage account %q: %s", storageAccountName, err)
+	}
+
+	if keysResp.Key1 == nil {
+		return nil, true, fmt.Errorf("Error retrieving keys for storage account %q: Key1 was nil", storageAccountName)
+	}
+	accessKey := *keysResp.Key1
+
+	storageClient, err := storage.NewClient(storageAccountName, accessKey, armClient.environment.StorageEndpointSuffix, storage.DefaultAPIVersion, true)
+	if err != nil {
+		return nil, true, fmt.Errorf("Error creating storage client for storage account %q: %s", storageAccountName, err)
+	}
+
+	blobClient := storageClient.GetBlobService()
+	return &blobClient, true, nil
+}